@@ -0,0 +1,210 @@
+package maestro
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Challenge is a parsed WWW-Authenticate challenge as sent by a registry
+// fronted by an OAuth2-style token server, e.g.
+//
+//	Bearer realm="https://auth.example.com/token",service="maestro",scope="registrant:pull"
+type Challenge struct {
+	Scheme     string
+	Parameters map[string]string
+}
+
+// Credential is the header a CredentialProvider wants attached to outgoing
+// requests. Expiry is the zero Time when the credential never expires.
+type Credential struct {
+	Header string
+	Value  string
+	Expiry time.Time
+}
+
+func (c Credential) expired() bool {
+	return !c.Expiry.IsZero() && !time.Now().Before(c.Expiry)
+}
+
+// CredentialProvider obtains the credential to attach to requests. challenge
+// is the zero value on the first attempt and is populated with the parsed
+// WWW-Authenticate header whenever the registry answers 401, so providers
+// that need a realm/service/scope (e.g. bearer-token-with-refresh) can act on
+// it.
+type CredentialProvider interface {
+	Credential(ctx context.Context, challenge Challenge) (Credential, error)
+}
+
+// StaticTokenProvider always returns the same X-Registry-Token value. It is
+// the provider New installs implicitly when a token is passed without an
+// explicit CredentialProvider.
+type StaticTokenProvider struct {
+	Token string
+}
+
+func (p StaticTokenProvider) Credential(_ context.Context, _ Challenge) (Credential, error) {
+	return Credential{Header: authHeader, Value: p.Token}, nil
+}
+
+// BasicAuthProvider authenticates with a fixed username/password pair.
+type BasicAuthProvider struct {
+	Username string
+	Password string
+}
+
+func (p BasicAuthProvider) Credential(_ context.Context, _ Challenge) (Credential, error) {
+	raw := p.Username + ":" + p.Password
+	return Credential{
+		Header: "Authorization",
+		Value:  "Basic " + base64.StdEncoding.EncodeToString([]byte(raw)),
+	}, nil
+}
+
+// TokenRefreshFunc fetches a bearer token for the given challenge, typically
+// by calling the token server named in challenge.Parameters["realm"] with the
+// "service" and "scope" parameters. It returns the token and its expiry.
+type TokenRefreshFunc func(ctx context.Context, challenge Challenge) (token string, expiry time.Time, err error)
+
+// BearerTokenProvider obtains a bearer token via Refresh whenever the cached
+// one has expired, so Maestro deployments can front the registry with an
+// OAuth2 token server without callers rolling their own refresh loop.
+type BearerTokenProvider struct {
+	Refresh TokenRefreshFunc
+}
+
+func (p *BearerTokenProvider) Credential(ctx context.Context, challenge Challenge) (Credential, error) {
+	token, expiry, err := p.Refresh(ctx, challenge)
+	if err != nil {
+		return Credential{}, fmt.Errorf("could not refresh bearer token: %w", err)
+	}
+	return Credential{Header: "Authorization", Value: "Bearer " + token, Expiry: expiry}, nil
+}
+
+// cachedCredential returns the last credential handed out, if any and still
+// valid.
+func (c *Client) cachedCredential() (Credential, bool) {
+	c.credMu.Lock()
+	defer c.credMu.Unlock()
+	if c.cred.Value == "" || c.cred.expired() {
+		return Credential{}, false
+	}
+	return c.cred, true
+}
+
+func (c *Client) cacheCredential(cred Credential) {
+	c.credMu.Lock()
+	c.cred = cred
+	c.credMu.Unlock()
+}
+
+// setAuthHeader attaches the current credential to req, fetching one from
+// the credential provider if none is cached yet.
+func (c *Client) setAuthHeader(ctx context.Context, req *http.Request) error {
+	if cred, ok := c.cachedCredential(); ok {
+		req.Header.Set(cred.Header, cred.Value)
+		return nil
+	}
+	cred, err := c.credentialProvider.Credential(ctx, Challenge{})
+	if err != nil {
+		return fmt.Errorf("could not obtain credential: %w", err)
+	}
+	c.cacheCredential(cred)
+	req.Header.Set(cred.Header, cred.Value)
+	return nil
+}
+
+// doAuthenticated runs req through the retry pipeline and, on a 401 response,
+// parses the WWW-Authenticate challenge, asks the credential provider for a
+// fresh credential and transparently retries the original request once.
+func (c *Client) doAuthenticated(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := c.setAuthHeader(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doWithRetry(ctx, req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge, ok := parseChallenge(resp.Header.Get("Www-Authenticate"))
+	if !ok {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	cred, err := c.credentialProvider.Credential(ctx, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain credential for challenge %q: %w", challenge.Scheme, err)
+	}
+	c.cacheCredential(cred)
+
+	retryReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retryReq.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("could not rewind request body: %w", err)
+		}
+	}
+	retryReq.Header.Set(cred.Header, cred.Value)
+
+	return c.doWithRetry(ctx, retryReq)
+}
+
+// parseChallenge parses the value of a WWW-Authenticate header into a
+// Challenge, tokenizing it the way docker/distribution's authchallenge.go
+// does: split on the first run of whitespace to get the scheme, then walk the
+// remainder honouring quoted-strings and comma separators to collect
+// key="value" parameters.
+func parseChallenge(header string) (Challenge, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return Challenge{}, false
+	}
+
+	sp := strings.IndexAny(header, " \t")
+	if sp < 0 {
+		return Challenge{Scheme: strings.ToLower(header), Parameters: map[string]string{}}, true
+	}
+
+	return Challenge{
+		Scheme:     strings.ToLower(header[:sp]),
+		Parameters: parseChallengeParams(strings.TrimSpace(header[sp+1:])),
+	}, true
+}
+
+func parseChallengeParams(s string) map[string]string {
+	params := map[string]string{}
+
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if comma := strings.IndexByte(s, ','); comma >= 0 {
+			value, s = strings.TrimSpace(s[:comma]), s[comma:]
+		} else {
+			value, s = strings.TrimSpace(s), ""
+		}
+
+		if key != "" {
+			params[key] = value
+		}
+	}
+
+	return params
+}
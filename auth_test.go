@@ -0,0 +1,67 @@
+package maestro
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChallengeSchemeOnly(t *testing.T) {
+	ch, ok := parseChallenge("Bearer")
+	if !ok {
+		t.Fatal("parseChallenge returned ok=false for a valid header")
+	}
+	want := Challenge{Scheme: "bearer", Parameters: map[string]string{}}
+	if !reflect.DeepEqual(ch, want) {
+		t.Fatalf("parseChallenge = %+v, want %+v", ch, want)
+	}
+}
+
+func TestParseChallengeEmptyHeader(t *testing.T) {
+	if _, ok := parseChallenge(""); ok {
+		t.Fatal("parseChallenge should return ok=false for an empty header")
+	}
+	if _, ok := parseChallenge("   "); ok {
+		t.Fatal("parseChallenge should return ok=false for a whitespace-only header")
+	}
+}
+
+func TestParseChallengeWithParams(t *testing.T) {
+	header := `Bearer realm="https://auth.example.com/token",service="maestro",scope="registrant:pull"`
+	ch, ok := parseChallenge(header)
+	if !ok {
+		t.Fatal("parseChallenge returned ok=false for a valid header")
+	}
+	if ch.Scheme != "bearer" {
+		t.Fatalf("Scheme = %q, want %q", ch.Scheme, "bearer")
+	}
+	want := map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "maestro",
+		"scope":   "registrant:pull",
+	}
+	if !reflect.DeepEqual(ch.Parameters, want) {
+		t.Fatalf("Parameters = %+v, want %+v", ch.Parameters, want)
+	}
+}
+
+func TestParseChallengeParamsUnquotedValue(t *testing.T) {
+	got := parseChallengeParams(`service=maestro, scope="registrant:pull"`)
+	want := map[string]string{"service": "maestro", "scope": "registrant:pull"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseChallengeParams = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseChallengeParamsUnterminatedQuote(t *testing.T) {
+	got := parseChallengeParams(`realm="https://auth.example.com/token`)
+	want := map[string]string{"realm": "https://auth.example.com/token"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseChallengeParams = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseChallengeParamsEmpty(t *testing.T) {
+	if got := parseChallengeParams(""); len(got) != 0 {
+		t.Fatalf("parseChallengeParams(\"\") = %+v, want empty map", got)
+	}
+}
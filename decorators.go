@@ -0,0 +1,241 @@
+package maestro
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redactedHeaders lists the headers WithLogging must never print verbatim.
+var redactedHeaders = []string{authHeader, "Authorization"}
+
+// WithCorrelationID returns a RequestDecorator that stamps every outgoing
+// request with a random X-Request-ID header, unless the caller already set
+// one.
+func WithCorrelationID() RequestDecorator {
+	return func(req *http.Request) (*http.Request, error) {
+		if req.Header.Get("X-Request-ID") == "" {
+			id, err := randomID()
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("X-Request-ID", id)
+		}
+		return req, nil
+	}
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate correlation id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// WithGzipRequest returns a RequestDecorator that gzip-compresses the
+// request body, if any, and sets Content-Encoding accordingly.
+func WithGzipRequest() RequestDecorator {
+	return func(req *http.Request) (*http.Request, error) {
+		if req.GetBody == nil {
+			return req, nil
+		}
+
+		raw, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		defer raw.Close()
+
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := io.Copy(gw, raw); err != nil {
+			return nil, fmt.Errorf("could not gzip request body: %w", err)
+		}
+		if err := gw.Close(); err != nil {
+			return nil, fmt.Errorf("could not gzip request body: %w", err)
+		}
+		compressed := buf.Bytes()
+
+		req.Body = io.NopCloser(bytes.NewReader(compressed))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(compressed)), nil
+		}
+		req.ContentLength = int64(len(compressed))
+		req.Header.Set("Content-Encoding", "gzip")
+
+		return req, nil
+	}
+}
+
+// WithGzipResponse returns a ResponseDecorator that transparently
+// decompresses a gzip-encoded response body.
+func WithGzipResponse() ResponseDecorator {
+	return func(resp *http.Response) (*http.Response, error) {
+		if resp.Header.Get("Content-Encoding") != "gzip" {
+			return resp, nil
+		}
+
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("could not create gzip reader: %w", err)
+		}
+		resp.Body = gzipBody{gr, resp.Body}
+		resp.Header.Del("Content-Encoding")
+		return resp, nil
+	}
+}
+
+// gzipBody closes both the decompressing reader and the underlying network
+// body when the caller closes the response body.
+type gzipBody struct {
+	*gzip.Reader
+	network io.Closer
+}
+
+func (b gzipBody) Close() error {
+	if err := b.Reader.Close(); err != nil {
+		return err
+	}
+	return b.network.Close()
+}
+
+// WithLogging returns a request/response decorator pair that logs every
+// call's method, URL and outcome via logger, redacting auth headers.
+func WithLogging(logger *log.Logger) (RequestDecorator, ResponseDecorator) {
+	type startTimeKey struct{}
+
+	request := func(req *http.Request) (*http.Request, error) {
+		logger.Printf("-> %s %s %s", req.Method, req.URL.String(), redactHeaders(req.Header))
+		return req.WithContext(context.WithValue(req.Context(), startTimeKey{}, time.Now())), nil
+	}
+
+	response := func(resp *http.Response) (*http.Response, error) {
+		var elapsed time.Duration
+		if start, ok := resp.Request.Context().Value(startTimeKey{}).(time.Time); ok {
+			elapsed = time.Since(start)
+		}
+		logger.Printf("<- %s %d (%s)", resp.Request.URL.String(), resp.StatusCode, elapsed)
+		return resp, nil
+	}
+
+	return request, response
+}
+
+func redactHeaders(h http.Header) http.Header {
+	clone := h.Clone()
+	for _, name := range redactedHeaders {
+		if clone.Get(name) != "" {
+			clone.Set(name, "REDACTED")
+		}
+	}
+	return clone
+}
+
+// WithTracing returns a request/response decorator pair that wraps every
+// call in an OpenTelemetry span named after the request path.
+func WithTracing(tracer trace.Tracer) (RequestDecorator, ResponseDecorator) {
+	request := func(req *http.Request) (*http.Request, error) {
+		ctx, _ := tracer.Start(req.Context(), req.URL.Path,
+			trace.WithAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.url", req.URL.String()),
+			),
+		)
+		return req.WithContext(ctx), nil
+	}
+
+	response := func(resp *http.Response) (*http.Response, error) {
+		span := trace.SpanFromContext(resp.Request.Context())
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", resp.StatusCode))
+		}
+		span.End()
+		return resp, nil
+	}
+
+	return request, response
+}
+
+// Metrics holds the Prometheus collectors WithMetrics records against.
+// Register them with a prometheus.Registerer via Collectors.
+type Metrics struct {
+	RequestsTotal   *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	InFlight        *prometheus.GaugeVec
+}
+
+// NewMetrics creates the default Prometheus collectors, labelled by route.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		RequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "maestro",
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "Total number of requests made to the registry, by route and status code.",
+		}, []string{"route", "status"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "maestro",
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests made to the registry, by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route"}),
+		InFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "maestro",
+			Subsystem: "client",
+			Name:      "in_flight_requests",
+			Help:      "Number of requests to the registry currently in flight, by route.",
+		}, []string{"route"}),
+	}
+}
+
+// Collectors returns every collector in m, ready for
+// registry.MustRegister(m.Collectors()...).
+func (m *Metrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.RequestsTotal, m.RequestDuration, m.InFlight}
+}
+
+// WithMetrics returns a request/response decorator pair that records request
+// count, latency and in-flight gauge against m, labelled by route.
+func WithMetrics(m *Metrics) (RequestDecorator, ResponseDecorator) {
+	type metricsState struct {
+		route string
+		start time.Time
+	}
+	type metricsKey struct{}
+
+	request := func(req *http.Request) (*http.Request, error) {
+		route := req.URL.Path
+		m.InFlight.WithLabelValues(route).Inc()
+		ctx := context.WithValue(req.Context(), metricsKey{}, metricsState{route: route, start: time.Now()})
+		return req.WithContext(ctx), nil
+	}
+
+	response := func(resp *http.Response) (*http.Response, error) {
+		state, ok := resp.Request.Context().Value(metricsKey{}).(metricsState)
+		if !ok {
+			return resp, nil
+		}
+		m.InFlight.WithLabelValues(state.route).Dec()
+		m.RequestDuration.WithLabelValues(state.route).Observe(time.Since(state.start).Seconds())
+		m.RequestsTotal.WithLabelValues(state.route, strconv.Itoa(resp.StatusCode)).Inc()
+		return resp, nil
+	}
+
+	return request, response
+}
@@ -0,0 +1,100 @@
+package maestro
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions controls a single List call. Limit is left to the server's
+// default when zero, and Cursor is left empty to fetch the first page.
+type ListOptions struct {
+	Cursor string
+	Limit  int
+}
+
+// listResponse mirrors the paginated shape the registry answers /query with
+// when no id is given.
+type listResponse struct {
+	Registrants []Registrant `json:"registrants"`
+	NextCursor  string       `json:"next_cursor"`
+}
+
+// List returns one page of registrants along with the cursor to pass as
+// ListOptions.Cursor to fetch the next page; an empty cursor means there is
+// no next page.
+func (c *Client) List(ctx context.Context, opts ListOptions) ([]Registrant, string, error) {
+	q := url.Values{}
+	if opts.Cursor != "" {
+		q.Set("cursor", opts.Cursor)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	u := c.getUrl(queryAllRoute)
+	if enc := q.Encode(); enc != "" {
+		u += "?" + enc
+	}
+
+	var page listResponse
+	if err := c.pipeline.Do(ctx, http.MethodGet, u, nil, &page); err != nil {
+		return nil, "", err
+	}
+
+	return page.Registrants, page.NextCursor, nil
+}
+
+// ListAll walks every page List returns, yielding one Registrant at a time so
+// callers can range over the whole registry without juggling cursors:
+//
+//	for reg, err := range client.ListAll(ctx) {
+//		if err != nil { ... }
+//	}
+func (c *Client) ListAll(ctx context.Context) iter.Seq2[Registrant, error] {
+	return func(yield func(Registrant, error) bool) {
+		var opts ListOptions
+		for {
+			regs, next, err := c.List(ctx, opts)
+			if err != nil {
+				yield(Registrant{}, err)
+				return
+			}
+			for _, reg := range regs {
+				if !yield(reg, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			opts.Cursor = next
+		}
+	}
+}
+
+// QueryMany batches lookups for multiple ids into a single request instead
+// of issuing len(ids) calls to Query. Ids the registry doesn't know about are
+// simply absent from the returned map.
+func (c *Client) QueryMany(ctx context.Context, ids []string) (map[string]Registrant, error) {
+	payload, err := json.Marshal(struct {
+		Ids []string `json:"ids"`
+	}{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	var regs []Registrant
+	if err := c.pipeline.Do(ctx, http.MethodPost, c.getUrl(queryAllRoute), payload, &regs); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]Registrant, len(regs))
+	for _, reg := range regs {
+		out[reg.Id] = reg
+	}
+	return out, nil
+}
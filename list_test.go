@@ -0,0 +1,55 @@
+package maestro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestListAllWalksEveryPage checks that ListAll follows next_cursor across
+// multiple pages and stops once the server returns an empty cursor.
+func TestListAllWalksEveryPage(t *testing.T) {
+	pages := map[string]listResponse{
+		"": {
+			Registrants: []Registrant{{Id: "svc-1"}, {Id: "svc-2"}},
+			NextCursor:  "page-2",
+		},
+		"page-2": {
+			Registrants: []Registrant{{Id: "svc-3"}},
+			NextCursor:  "",
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok", "svc-1", nil)
+
+	var ids []string
+	for reg, err := range c.ListAll(context.Background()) {
+		if err != nil {
+			t.Fatalf("ListAll() error = %v", err)
+		}
+		ids = append(ids, reg.Id)
+	}
+
+	want := []string{"svc-1", "svc-2", "svc-3"}
+	if len(ids) != len(want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("got %v, want %v", ids, want)
+		}
+	}
+}
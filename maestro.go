@@ -1,12 +1,12 @@
 package maestro
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,10 +16,61 @@ type (
 		AuthToken string
 		Id        string
 		Client    *http.Client
+
+		maxRetries  int
+		minBackoff  time.Duration
+		maxBackoff  time.Duration
+		retryPolicy RetryPolicy
+
+		credentialProvider CredentialProvider
+		credMu             sync.Mutex
+		cred               Credential
+
+		resolver *resolverCache
+
+		addrMu   sync.Mutex
+		lastAddr string
+
+		pipeline           requestPipeline
+		requestDecorators  []RequestDecorator
+		responseDecorators []ResponseDecorator
 	}
 	ClientConfig struct {
 		Timeout   time.Duration
 		Transport *http.Transport
+
+		// MaxRetries is the number of attempts after the first that the
+		// client will make for a request the RetryPolicy deems retryable.
+		// Nil leaves defaultMaxRetries; a pointer to 0 disables retries.
+		MaxRetries *int
+		// MinBackoff and MaxBackoff bound the exponential backoff applied
+		// between attempts: sleep = min(MaxBackoff, MinBackoff*2^attempt),
+		// with full jitter applied on top. Nil leaves the respective
+		// default.
+		MinBackoff *time.Duration
+		MaxBackoff *time.Duration
+		// RetryPolicy classifies a response/error pair as retryable or not.
+		// Defaults to defaultRetryPolicy.
+		RetryPolicy RetryPolicy
+
+		// CredentialProvider supplies the auth header for outgoing requests
+		// and is consulted again whenever the registry answers 401 with a
+		// WWW-Authenticate challenge. Defaults to a StaticTokenProvider
+		// wrapping the token passed to New.
+		CredentialProvider CredentialProvider
+
+		// PingInterval should match the interval passed to StartPing. Watch
+		// and WatchAll cache entries are kept for roughly this long before
+		// being considered stale. Defaults to defaultCacheTTL.
+		PingInterval time.Duration
+
+		// RequestDecorators and ResponseDecorators let callers append
+		// cross-cutting behaviour (correlation IDs, compression, logging,
+		// tracing, metrics, ...) to every request without forking the SDK.
+		// They run in order, request decorators before the request is sent
+		// and response decorators after it is received.
+		RequestDecorators  []RequestDecorator
+		ResponseDecorators []ResponseDecorator
 	}
 	Registrant struct {
 		Id      string `json:"id"`
@@ -34,15 +85,27 @@ const (
 
 	apiPrefix = "/api/v1"
 
-	register   Route = "/register"
-	deregister Route = "/deregister"
-	ping       Route = "/ping"
-	query      Route = "/query?id=%s"
+	register      Route = "/register"
+	deregister    Route = "/deregister"
+	ping          Route = "/ping"
+	query         Route = "/query?id=%s"
+	queryAllRoute Route = "/query"
 )
 
 func New(baseUrl, token, id string, config *ClientConfig) *Client {
 	httpClient := &http.Client{Timeout: 3 * time.Second}
-	c := Client{BaseUrl: strings.TrimSuffix(baseUrl, "/"), AuthToken: token, Id: id}
+	c := Client{
+		BaseUrl:     strings.TrimSuffix(baseUrl, "/"),
+		AuthToken:   token,
+		Id:          id,
+		maxRetries:  defaultMaxRetries,
+		minBackoff:  defaultMinBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		retryPolicy: defaultRetryPolicy,
+	}
+	c.credentialProvider = StaticTokenProvider{Token: token}
+	c.resolver = newResolverCache()
+	c.pipeline = requestPipeline{c: &c}
 
 	if config != nil {
 		// set HTTP client timeout
@@ -53,6 +116,27 @@ func New(baseUrl, token, id string, config *ClientConfig) *Client {
 		if config.Transport != nil {
 			httpClient.Transport = config.Transport
 		}
+		// set retry behaviour
+		if config.MaxRetries != nil {
+			c.maxRetries = *config.MaxRetries
+		}
+		if config.MinBackoff != nil {
+			c.minBackoff = *config.MinBackoff
+		}
+		if config.MaxBackoff != nil {
+			c.maxBackoff = *config.MaxBackoff
+		}
+		if config.RetryPolicy != nil {
+			c.retryPolicy = config.RetryPolicy
+		}
+		if config.CredentialProvider != nil {
+			c.credentialProvider = config.CredentialProvider
+		}
+		if config.PingInterval > 0 {
+			c.resolver.ttl = config.PingInterval
+		}
+		c.requestDecorators = config.RequestDecorators
+		c.responseDecorators = config.ResponseDecorators
 	}
 
 	c.Client = httpClient
@@ -61,8 +145,9 @@ func New(baseUrl, token, id string, config *ClientConfig) *Client {
 }
 
 // Register registered the service combined with the given public address
-// with the registry
-func (c *Client) Register(address string) error {
+// with the registry. The request is retried on transient failures according
+// to the Client's retry policy.
+func (c *Client) Register(ctx context.Context, address string) error {
 	reg := Registrant{
 		Id:      c.Id,
 		Address: address,
@@ -73,28 +158,18 @@ func (c *Client) Register(address string) error {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.getUrl(register), bytes.NewBuffer(regJson))
-	if err != nil {
+	if err := c.pipeline.Do(ctx, http.MethodPost, c.getUrl(register), regJson, nil); err != nil {
 		return err
 	}
 
-	c.addAuthHeader(req)
-
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("received non-success status code (%d)", resp.StatusCode)
-	}
+	c.setLastAddress(address)
 
 	return nil
 }
 
-// Deregister removes the service from the registry
-func (c *Client) Deregister() error {
+// Deregister removes the service from the registry. The request is retried
+// on transient failures according to the Client's retry policy.
+func (c *Client) Deregister(ctx context.Context) error {
 	reg := Registrant{
 		Id:      c.Id,
 		Address: "",
@@ -105,88 +180,46 @@ func (c *Client) Deregister() error {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodDelete, c.getUrl(deregister), bytes.NewBuffer(regJson))
-	if err != nil {
-		return err
-	}
-
-	c.addAuthHeader(req)
-
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("received non-success status code (%d)", resp.StatusCode)
-	}
-
-	return nil
+	return c.pipeline.Do(ctx, http.MethodDelete, c.getUrl(deregister), regJson, nil)
 }
 
-// StartPing pings the Maestro instance at the supplied interval. Should be started
-// as a goroutine. Can be stopped via the context's Cancel function.
-func (c *Client) StartPing(ctx context.Context, interval time.Duration) {
-	t := time.NewTicker(interval)
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-t.C:
-			if err := c.Ping(); err != nil {
-				fmt.Println("ping error: " + err.Error())
-			}
-		default:
-		}
-	}
+// Ping notifies the registry that the service is still alive. The request is
+// retried on transient failures according to the Client's retry policy.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.pipeline.Do(ctx, http.MethodPut, fmt.Sprintf("%s?id=%s", c.getUrl(ping), c.Id), nil, nil)
 }
 
-func (c *Client) Ping() error {
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s?id=%s", c.getUrl(ping), c.Id), nil)
-	if err != nil {
-		return err
-	}
-
-	c.addAuthHeader(req)
-
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return err
-	}
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("received non-success status code (%d)", resp.StatusCode)
+// Query queries Maestro for the info on the specified ID. The request is
+// retried on transient failures according to the Client's retry policy.
+func (c *Client) Query(ctx context.Context, id string) (Registrant, error) {
+	var entry Registrant
+	if err := c.pipeline.Do(ctx, http.MethodGet, fmt.Sprintf(c.getUrl(query), id), nil, &entry); err != nil {
+		return Registrant{}, err
 	}
-
-	return nil
+	return entry, nil
 }
 
-// Query queries Maestro for the info on the specified ID
-func (c *Client) Query(id string) (Registrant, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(c.getUrl(query), id), nil)
-	if err != nil {
-		return Registrant{}, fmt.Errorf("could not create request: %s", err.Error())
-	}
-	c.addAuthHeader(req)
-
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return Registrant{}, fmt.Errorf("could not execute query request: %s", err.Error())
-	}
-	defer resp.Body.Close()
+// StatusError is returned when the registry answers a request with a status
+// code of 400 or above that the retry policy did not consider worth retrying
+// (or that exhausted its retries).
+type StatusError struct {
+	StatusCode int
+}
 
-	var entry Registrant
-	err = json.NewDecoder(resp.Body).Decode(&entry)
-	if err != nil {
-		return Registrant{}, fmt.Errorf("could not decode JSON: %s", err.Error())
-	}
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received non-success status code (%d)", e.StatusCode)
+}
 
-	return entry, nil
+func (c *Client) setLastAddress(address string) {
+	c.addrMu.Lock()
+	c.lastAddr = address
+	c.addrMu.Unlock()
 }
 
-func (c *Client) addAuthHeader(r *http.Request) {
-	r.Header.Add(authHeader, c.AuthToken)
+func (c *Client) lastAddress() (string, bool) {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+	return c.lastAddr, c.lastAddr != ""
 }
 
 func (c *Client) getUrl(r Route) string {
@@ -0,0 +1,118 @@
+package maestro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// pingBackoffCap bounds how far the inter-ping delay can grow while
+// unhealthy, expressed as a multiple of PingOptions.Interval.
+const pingBackoffCap = 8
+
+// PingOptions configures StartPing.
+type PingOptions struct {
+	// Interval is the normal, healthy cadence between pings.
+	Interval time.Duration
+	// FailureThreshold is the number of consecutive ping failures at which
+	// OnUnhealthy fires and backoff kicks in. Defaults to 1.
+	FailureThreshold int
+	// OnUnhealthy is called once when FailureThreshold consecutive pings
+	// have failed.
+	OnUnhealthy func(err error)
+	// OnRecovered is called once when a ping succeeds after OnUnhealthy
+	// fired.
+	OnRecovered func()
+	// AutoReregister replays the last successful Register call when a ping
+	// fails with a 404, i.e. the registry no longer knows this id.
+	AutoReregister bool
+}
+
+// PingEvent reports the outcome of a single ping attempt.
+type PingEvent struct {
+	Time    time.Time
+	Err     error
+	Healthy bool
+}
+
+// StartPing pings the registry at opts.Interval, started as a goroutine and
+// stopped via ctx. Every attempt is reported on the returned channel, which
+// is closed once ctx is done. While unhealthy the interval backs off
+// exponentially, capped at opts.Interval*8, instead of hammering a dead
+// registry; it resumes the normal cadence as soon as a ping succeeds again.
+// With opts.AutoReregister set, a 404 ping response (the registry doesn't
+// know this id) triggers replaying the last Register call.
+func (c *Client) StartPing(ctx context.Context, opts PingOptions) (<-chan PingEvent, error) {
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("maestro: PingOptions.Interval must be positive")
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 1
+	}
+
+	ch := make(chan PingEvent, 1)
+	go c.pingLoop(ctx, opts, ch)
+	return ch, nil
+}
+
+func (c *Client) pingLoop(ctx context.Context, opts PingOptions, ch chan<- PingEvent) {
+	defer close(ch)
+
+	interval := opts.Interval
+	maxBackoff := opts.Interval * pingBackoffCap
+	failures := 0
+	unhealthy := false
+
+	for {
+		if !sleep(ctx, interval) {
+			return
+		}
+
+		err := c.Ping(ctx)
+		if err != nil && opts.AutoReregister && isNotFound(err) {
+			if addr, ok := c.lastAddress(); ok {
+				err = c.Register(ctx, addr)
+			}
+		}
+		healthy := err == nil
+
+		select {
+		case ch <- PingEvent{Time: time.Now(), Err: err, Healthy: healthy}:
+		case <-ctx.Done():
+			return
+		}
+
+		if healthy {
+			failures = 0
+			if unhealthy {
+				unhealthy = false
+				interval = opts.Interval
+				if opts.OnRecovered != nil {
+					opts.OnRecovered()
+				}
+			}
+			continue
+		}
+
+		failures++
+		if failures == opts.FailureThreshold {
+			unhealthy = true
+			if opts.OnUnhealthy != nil {
+				opts.OnUnhealthy(err)
+			}
+		}
+		if unhealthy {
+			interval *= 2
+			if interval > maxBackoff {
+				interval = maxBackoff
+			}
+		}
+	}
+}
+
+func isNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,103 @@
+package maestro
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPingLoopReportsUnhealthyAndRecovers drives pingLoop against a server
+// that fails every ping until a threshold of attempts has been made, then
+// starts succeeding, and checks that OnUnhealthy/OnRecovered fire exactly
+// once each and that every attempt is reported on the event channel.
+func TestPingLoopReportsUnhealthyAndRecovers(t *testing.T) {
+	const failFor = 2
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= failFor {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok", "svc-1", &ClientConfig{MaxRetries: new(int)})
+
+	var unhealthyCalls, recoveredCalls int32
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ch, err := c.StartPing(ctx, PingOptions{
+		Interval:         20 * time.Millisecond,
+		FailureThreshold: failFor,
+		OnUnhealthy:      func(error) { atomic.AddInt32(&unhealthyCalls, 1) },
+		OnRecovered:      func() { atomic.AddInt32(&recoveredCalls, 1) },
+	})
+	if err != nil {
+		t.Fatalf("StartPing() error = %v", err)
+	}
+
+	var events []PingEvent
+	for ev := range ch {
+		events = append(events, ev)
+		if ev.Healthy {
+			cancel()
+		}
+	}
+
+	if len(events) < failFor+1 {
+		t.Fatalf("got %d ping events, want at least %d", len(events), failFor+1)
+	}
+	for i := 0; i < failFor; i++ {
+		if events[i].Healthy {
+			t.Fatalf("event %d: Healthy = true, want false (still within failFor)", i)
+		}
+	}
+	if !events[len(events)-1].Healthy {
+		t.Fatal("last event: Healthy = false, want true")
+	}
+	if got := atomic.LoadInt32(&unhealthyCalls); got != 1 {
+		t.Fatalf("OnUnhealthy called %d times, want 1", got)
+	}
+	if got := atomic.LoadInt32(&recoveredCalls); got != 1 {
+		t.Fatalf("OnRecovered called %d times, want 1", got)
+	}
+}
+
+// TestPingLoopStopsOnContextCancel checks that the event channel is closed
+// once ctx is done, rather than leaking the goroutine.
+func TestPingLoopStopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok", "svc-1", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := c.StartPing(ctx, PingOptions{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("StartPing() error = %v", err)
+	}
+
+	<-ch // wait for at least one event
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ping event channel did not close within 2s of ctx cancel")
+	}
+}
@@ -0,0 +1,95 @@
+package maestro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RequestDecorator mutates an outgoing request before it is sent, e.g. to
+// inject headers, compress the body or start a trace span. It returns the
+// (possibly replaced) request to send, modelled on go-autorest's Preparer.
+type RequestDecorator func(*http.Request) (*http.Request, error)
+
+// ResponseDecorator runs after a response is received, e.g. to decompress
+// the body, end a trace span or record metrics. It returns the (possibly
+// replaced) response to continue processing, modelled on go-autorest's
+// Responder.
+type ResponseDecorator func(*http.Response) (*http.Response, error)
+
+// requestPipeline is the single dispatch path every public method funnels
+// through: build the request, run it through the configured decorators,
+// authenticate/retry it, run the response through the configured decorators,
+// then decode the body into out.
+type requestPipeline struct {
+	c *Client
+}
+
+// Do builds a method request against url carrying body as its payload, runs
+// it through the pipeline and decodes the response into out. body and out
+// may both be nil.
+func (p *requestPipeline) Do(ctx context.Context, method, url string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+
+	for _, decorate := range p.c.requestDecorators {
+		if req, err = decorate(req); err != nil {
+			return fmt.Errorf("request decorator: %w", err)
+		}
+	}
+
+	resp, err := p.c.doAuthenticated(ctx, req)
+	if err != nil {
+		// req's context may carry state a response decorator needs to tear
+		// down (an open trace span, an in-flight gauge, ...); run the
+		// decorators against a response wrapping that context so a
+		// transport-level failure doesn't leak it, same as a received one.
+		p.runResponseDecorators(&http.Response{Request: req})
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp, err = p.runResponseDecorators(resp); err != nil {
+		return fmt.Errorf("response decorator: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	if out == nil {
+		// Drain the body so the transport can reuse the connection; Close
+		// alone on an unread body forces net/http to discard it.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("could not decode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// runResponseDecorators runs resp through every configured response
+// decorator in order, returning the (possibly replaced) response and the
+// first decorator error, if any.
+func (p *requestPipeline) runResponseDecorators(resp *http.Response) (*http.Response, error) {
+	var err error
+	for _, decorate := range p.c.responseDecorators {
+		if resp, err = decorate(resp); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
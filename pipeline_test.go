@@ -0,0 +1,91 @@
+package maestro
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestPipelineRunsResponseDecoratorsInOrder(t *testing.T) {
+	var order []string
+	c := &Client{
+		responseDecorators: []ResponseDecorator{
+			func(resp *http.Response) (*http.Response, error) {
+				order = append(order, "first")
+				return resp, nil
+			},
+			func(resp *http.Response) (*http.Response, error) {
+				order = append(order, "second")
+				return resp, nil
+			},
+		},
+	}
+	p := &requestPipeline{c: c}
+
+	resp := &http.Response{Request: &http.Request{}}
+	if _, err := p.runResponseDecorators(resp); err != nil {
+		t.Fatalf("runResponseDecorators returned error: %v", err)
+	}
+
+	want := []string{"first", "second"}
+	if len(order) != len(want) || order[0] != want[0] || order[1] != want[1] {
+		t.Fatalf("decorator order = %v, want %v", order, want)
+	}
+}
+
+func TestPipelineRunsResponseDecoratorsOnTransportError(t *testing.T) {
+	ran := false
+	c := &Client{
+		Client: &http.Client{Transport: &http.Transport{
+			DialContext: func(context.Context, string, string) (net.Conn, error) {
+				return nil, errors.New("dial refused")
+			},
+		}},
+		credentialProvider: StaticTokenProvider{Token: "tok"},
+		responseDecorators: []ResponseDecorator{
+			func(resp *http.Response) (*http.Response, error) {
+				ran = true
+				if resp.Request == nil {
+					t.Fatal("response decorator did not receive the original request")
+				}
+				return resp, nil
+			},
+		},
+	}
+	p := &requestPipeline{c: c}
+
+	if err := p.Do(context.Background(), http.MethodGet, "http://registry.invalid/api/v1/ping", nil, nil); err == nil {
+		t.Fatal("Do() error = nil, want the transport error")
+	}
+
+	if !ran {
+		t.Fatal("response decorators did not run on the synthetic error-path response")
+	}
+}
+
+func TestPipelineStopsAtFirstDecoratorError(t *testing.T) {
+	wantErr := errors.New("boom")
+	secondRan := false
+	c := &Client{
+		responseDecorators: []ResponseDecorator{
+			func(resp *http.Response) (*http.Response, error) {
+				return resp, wantErr
+			},
+			func(resp *http.Response) (*http.Response, error) {
+				secondRan = true
+				return resp, nil
+			},
+		},
+	}
+	p := &requestPipeline{c: c}
+
+	_, err := p.runResponseDecorators(&http.Response{Request: &http.Request{}})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("runResponseDecorators error = %v, want %v", err, wantErr)
+	}
+	if secondRan {
+		t.Fatal("runResponseDecorators ran a decorator after one returned an error")
+	}
+}
@@ -0,0 +1,227 @@
+package maestro
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotCached is returned by Resolve when id has not been observed by a
+// Watch/WatchAll goroutine yet, or its cache entry has expired.
+var ErrNotCached = errors.New("maestro: registrant not in cache")
+
+const (
+	// longPollTimeout bounds a single long-poll attempt against /query.
+	longPollTimeout = 30 * time.Second
+	// fallbackPollInterval is used once a registry is observed to answer
+	// /query immediately instead of holding the request open, and as the
+	// backoff after a transient error.
+	fallbackPollInterval = 5 * time.Second
+	// defaultCacheTTL is used when ClientConfig.PingInterval is unset.
+	defaultCacheTTL = 30 * time.Second
+)
+
+type cacheEntry struct {
+	registrant Registrant
+	expiresAt  time.Time
+}
+
+// resolverCache is the in-memory store Watch/WatchAll populate and Resolve
+// reads from.
+type resolverCache struct {
+	mu   sync.RWMutex
+	ttl  time.Duration
+	byId map[string]cacheEntry
+}
+
+func newResolverCache() *resolverCache {
+	return &resolverCache{ttl: defaultCacheTTL, byId: map[string]cacheEntry{}}
+}
+
+func (r *resolverCache) get(id string) (Registrant, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.byId[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return Registrant{}, false
+	}
+	return entry.registrant, true
+}
+
+func (r *resolverCache) set(id string, reg Registrant) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byId[id] = cacheEntry{registrant: reg, expiresAt: time.Now().Add(r.ttl)}
+}
+
+func (r *resolverCache) delete(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byId, id)
+}
+
+// Resolve returns the last known Registrant for id from the local cache
+// populated by Watch/WatchAll. It never makes a network call, so callers
+// must have a Watch (or WatchAll) goroutine running for id, or get
+// ErrNotCached.
+func (c *Client) Resolve(id string) (Registrant, error) {
+	if reg, ok := c.resolver.get(id); ok {
+		return reg, nil
+	}
+	return Registrant{}, ErrNotCached
+}
+
+// Watch long-polls the registry for changes to id and emits a Registrant on
+// the returned channel every time it changes, keeping the local cache that
+// Resolve reads from up to date. It falls back to fixed-interval polling if
+// the server answers /query immediately rather than holding it open for
+// longPollTimeout. The channel is closed when ctx is done or id is
+// deregistered (404).
+func (c *Client) Watch(ctx context.Context, id string) (<-chan Registrant, error) {
+	if _, err := c.Query(ctx, id); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Registrant, 1)
+	go c.watchLoop(ctx, id, ch)
+	return ch, nil
+}
+
+func (c *Client) watchLoop(ctx context.Context, id string, ch chan<- Registrant) {
+	defer close(ch)
+	defer c.resolver.delete(id)
+
+	var last Registrant
+	haveLast := false
+	interval := longPollTimeout
+
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, interval)
+		start := time.Now()
+		reg, err := c.Query(pollCtx, id)
+		elapsed := time.Since(start)
+		cancel()
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if isNotFound(err) {
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				continue // long-poll timed out naturally, ask again
+			}
+			if !sleep(ctx, fallbackPollInterval) {
+				return
+			}
+			continue
+		}
+
+		if interval == longPollTimeout && elapsed < time.Second {
+			interval = fallbackPollInterval
+		}
+
+		c.resolver.set(id, reg)
+
+		if !haveLast || reg != last {
+			haveLast, last = true, reg
+			select {
+			case ch <- reg:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if interval == fallbackPollInterval {
+			if !sleep(ctx, fallbackPollInterval) {
+				return
+			}
+		}
+	}
+}
+
+// WatchAll streams every registrant change across the whole registry so
+// sidecars can build a live routing table without issuing a Watch per id.
+func (c *Client) WatchAll(ctx context.Context) (<-chan Registrant, error) {
+	ch := make(chan Registrant, 16)
+	go c.watchAllLoop(ctx, ch)
+	return ch, nil
+}
+
+func (c *Client) watchAllLoop(ctx context.Context, ch chan<- Registrant) {
+	defer close(ch)
+
+	interval := longPollTimeout
+
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, interval)
+		start := time.Now()
+		regs, err := c.queryAllRegistrants(pollCtx)
+		elapsed := time.Since(start)
+		cancel()
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				continue
+			}
+			if !sleep(ctx, fallbackPollInterval) {
+				return
+			}
+			continue
+		}
+
+		if interval == longPollTimeout && elapsed < time.Second {
+			interval = fallbackPollInterval
+		}
+
+		for _, reg := range regs {
+			prev, ok := c.resolver.get(reg.Id)
+			c.resolver.set(reg.Id, reg)
+			if ok && prev == reg {
+				continue
+			}
+			select {
+			case ch <- reg:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if interval == fallbackPollInterval {
+			if !sleep(ctx, fallbackPollInterval) {
+				return
+			}
+		}
+	}
+}
+
+// queryAllRegistrants fetches every registrant known to the server by
+// draining ListAll, which walks the same {registrants, next_cursor} shape
+// List decodes - queryAllRegistrants and List hit the identical /query
+// route, so they must agree on the response shape.
+func (c *Client) queryAllRegistrants(ctx context.Context) ([]Registrant, error) {
+	var all []Registrant
+	for reg, err := range c.ListAll(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, reg)
+	}
+	return all, nil
+}
+
+// sleep waits for d or ctx cancellation, reporting which happened.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
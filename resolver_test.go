@@ -0,0 +1,112 @@
+package maestro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResolverCacheGetSetExpiry(t *testing.T) {
+	reg := Registrant{Id: "svc-1", Address: "10.0.0.1:8080"}
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wait    time.Duration
+		wantOk  bool
+		skipSet bool
+	}{
+		{name: "missing id", skipSet: true, ttl: time.Minute, wantOk: false},
+		{name: "fresh entry", ttl: time.Minute, wait: 0, wantOk: true},
+		{name: "expired entry", ttl: 10 * time.Millisecond, wait: 20 * time.Millisecond, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &resolverCache{ttl: tt.ttl, byId: map[string]cacheEntry{}}
+			if !tt.skipSet {
+				c.set(reg.Id, reg)
+			}
+			time.Sleep(tt.wait)
+
+			got, ok := c.get(reg.Id)
+			if ok != tt.wantOk {
+				t.Fatalf("get() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got != reg {
+				t.Fatalf("get() = %+v, want %+v", got, reg)
+			}
+		})
+	}
+}
+
+func TestResolverCacheDelete(t *testing.T) {
+	c := newResolverCache()
+	reg := Registrant{Id: "svc-1", Address: "10.0.0.1:8080"}
+	c.set(reg.Id, reg)
+
+	if _, ok := c.get(reg.Id); !ok {
+		t.Fatal("get() after set = false, want true")
+	}
+
+	c.delete(reg.Id)
+	if _, ok := c.get(reg.Id); ok {
+		t.Fatal("get() after delete = true, want false")
+	}
+}
+
+// TestWatchLoopFallsBackToPollingAndDelivers exercises watchLoop against a
+// registry that answers /query immediately (never holding the long-poll
+// open), which should make it switch to fallbackPollInterval and still
+// deliver every change on the returned channel. addresses[0] is consumed by
+// Watch's own existence check before watchLoop starts, so the loop's first
+// observed address (addresses[1]) is always delivered as the initial value,
+// followed by one delivery per subsequent change.
+func TestWatchLoopFallsBackToPollingAndDelivers(t *testing.T) {
+	addresses := []string{"10.0.0.0:8080", "10.0.0.1:8080", "10.0.0.2:8080"}
+	var calls int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idx := calls
+		if idx >= len(addresses) {
+			idx = len(addresses) - 1
+		}
+		calls++
+		json.NewEncoder(w).Encode(Registrant{Id: "svc-1", Address: addresses[idx]})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok", "svc-1", nil)
+
+	// watchLoop sleeps fallbackPollInterval between polls once it has
+	// switched off the long-poll cadence, so the second delivery lands
+	// roughly fallbackPollInterval after the first.
+	ctx, cancel := context.WithTimeout(context.Background(), fallbackPollInterval+2*time.Second)
+	defer cancel()
+
+	ch, err := c.Watch(ctx, "svc-1")
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	want := addresses[1:]
+	var got []string
+	for reg := range ch {
+		got = append(got, reg.Address)
+		if len(got) == len(want) {
+			cancel()
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("received %d registrant updates, want %d: %v", len(got), len(want), got)
+	}
+	for i, addr := range want {
+		if got[i] != addr {
+			t.Fatalf("update %d = %q, want %q", i, got[i], addr)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package maestro
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a request should be retried given the response
+// (which may be nil on a transport-level failure) and the error returned by
+// Client.Do. Returning true retries the request; returning false surfaces the
+// response/error to the caller as-is.
+type RetryPolicy func(resp *http.Response, err error) bool
+
+// defaultRetryPolicy retries on network errors, 5xx responses and the two
+// status codes that explicitly ask the caller to back off and try again
+// (408 Request Timeout, 429 Too Many Requests). Any other 4xx is considered a
+// permanent failure and is not retried.
+func defaultRetryPolicy(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusRequestTimeout:
+		return true
+	case resp.StatusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+)
+
+// doWithRetry executes req, retrying according to c.retryPolicy with full
+// jitter exponential backoff between attempts. Between attempts the body is
+// rewound via req.GetBody, which http.NewRequest populates automatically for
+// the buffer/reader types the pipeline builds requests with.
+func (c *Client) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for attempt := 0; ; attempt++ {
+		// Clone from req's own context, not ctx directly: request decorators
+		// (WithTracing's span, WithMetrics's in-flight state, ...) attach
+		// their state to req's context via WithContext, and cloning with ctx
+		// instead would silently discard it before the call is made.
+		attemptReq := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if attemptReq.Body, err = req.GetBody(); err != nil {
+				return nil, fmt.Errorf("could not rewind request body: %w", err)
+			}
+		}
+
+		resp, err = c.Client.Do(attemptReq)
+
+		if attempt >= c.maxRetries || !c.retryPolicy(resp, err) {
+			return resp, err
+		}
+
+		wait := backoffFor(attempt, c.minBackoff, c.maxBackoff)
+		if resp != nil {
+			if ra, ok := retryAfter(resp); ok {
+				wait = ra
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoffFor computes min(maxBackoff, minBackoff*2^attempt) and then applies
+// full jitter, i.e. a uniform random duration between 0 and that ceiling.
+// A non-positive ceiling (minBackoff of 0, or a shift that overflows) falls
+// back to maxBackoff.
+func backoffFor(attempt int, minBackoff, maxBackoff time.Duration) time.Duration {
+	ceiling := minBackoff << attempt
+	if ceiling <= 0 || ceiling > maxBackoff {
+		ceiling = maxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
+
+// retryAfter honours a Retry-After header on 429/503 responses, supporting
+// both the delay-seconds and the HTTP-date form. A malformed or absent header,
+// or a date that is already past, is ignored in favour of the computed backoff.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package maestro
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoffForCapsAtMaxBackoff(t *testing.T) {
+	const maxBackoff = 2 * time.Second
+	for attempt := 0; attempt < 20; attempt++ {
+		wait := backoffFor(attempt, 100*time.Millisecond, maxBackoff)
+		if wait < 0 || wait > maxBackoff {
+			t.Fatalf("attempt %d: backoffFor returned %s, want in [0, %s]", attempt, wait, maxBackoff)
+		}
+	}
+}
+
+func TestBackoffForZeroMinBackoffFallsBackToMaxBackoff(t *testing.T) {
+	const maxBackoff = 2 * time.Second
+	if wait := backoffFor(0, 0, maxBackoff); wait < 0 || wait >= maxBackoff {
+		t.Fatalf("backoffFor with minBackoff=0 = %s, want in [0, %s)", wait, maxBackoff)
+	}
+}
+
+func TestBackoffForZeroMaxBackoff(t *testing.T) {
+	if wait := backoffFor(0, 100*time.Millisecond, 0); wait != 0 {
+		t.Fatalf("backoffFor with maxBackoff=0 = %s, want 0", wait)
+	}
+}
+
+func TestRetryAfterDelaySeconds(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"5"}},
+	}
+	d, ok := retryAfter(resp)
+	if !ok || d != 5*time.Second {
+		t.Fatalf("retryAfter(%q) = (%s, %v), want (5s, true)", "5", d, ok)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	when := time.Now().Add(30 * time.Second).UTC()
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{when.Format(http.TimeFormat)}},
+	}
+	d, ok := retryAfter(resp)
+	if !ok || d <= 0 || d > 31*time.Second {
+		t.Fatalf("retryAfter(%q) = (%s, %v), want a positive duration near 30s", when.Format(http.TimeFormat), d, ok)
+	}
+}
+
+func TestRetryAfterIgnoredOnOtherStatuses(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{"Retry-After": []string{"5"}},
+	}
+	if _, ok := retryAfter(resp); ok {
+		t.Fatal("retryAfter should only apply to 429/503 responses")
+	}
+}
+
+func TestRetryAfterMalformedHeader(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"not-a-valid-value"}},
+	}
+	if _, ok := retryAfter(resp); ok {
+		t.Fatal("retryAfter should ignore a malformed Retry-After header")
+	}
+}